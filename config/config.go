@@ -0,0 +1,313 @@
+// Package config loads the runtime configuration for the service. Values
+// are resolved with flags taking precedence over environment variables,
+// which in turn take precedence over an optional configuration file,
+// which in turn overrides the built-in defaults.
+package config
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/TonyJCaruana/go-rest-template/problem"
+)
+
+// envPrefix namespaces every environment variable this package reads, to
+// avoid colliding with unrelated variables in the container's environment.
+const envPrefix = "GRT_"
+
+// Duration is a time.Duration that marshals to and from the same
+// human-readable strings accepted on the command line and in environment
+// variables (e.g. "30s"), so a value written as readTimeout: 30s in a
+// YAML/JSON config file parses the same way it would as a flag or env var,
+// rather than requiring raw nanoseconds.
+type Duration time.Duration
+
+// MarshalJSON renders the duration as its string form, e.g. "30s".
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// UnmarshalJSON accepts either a duration string ("30s") or a plain
+// number of nanoseconds, for callers that prefer to write raw integers.
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var v interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	switch val := v.(type) {
+	case string:
+		parsed, err := time.ParseDuration(val)
+		if err != nil {
+			return fmt.Errorf("config: invalid duration %q: %w", val, err)
+		}
+		*d = Duration(parsed)
+		return nil
+	case float64:
+		*d = Duration(time.Duration(val))
+		return nil
+	default:
+		return fmt.Errorf("config: invalid duration %v", v)
+	}
+}
+
+// MarshalYAML renders the duration as its string form, e.g. "30s".
+func (d Duration) MarshalYAML() (interface{}, error) {
+	return time.Duration(d).String(), nil
+}
+
+// UnmarshalYAML accepts either a duration string ("30s") or a plain number
+// of nanoseconds, for callers that prefer to write raw integers.
+func (d *Duration) UnmarshalYAML(value *yaml.Node) error {
+	// Try the integer form first: any YAML scalar, a bare number included,
+	// also decodes into a string.
+	var n int64
+	if err := value.Decode(&n); err == nil {
+		*d = Duration(time.Duration(n))
+		return nil
+	}
+
+	var s string
+	if err := value.Decode(&s); err != nil {
+		return fmt.Errorf("config: invalid duration %q", value.Value)
+	}
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		return fmt.Errorf("config: invalid duration %q: %w", s, err)
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+// Config is the effective runtime configuration for the service.
+type Config struct {
+	Addr             string   `json:"addr" yaml:"addr"`
+	AdminAddr        string   `json:"adminAddr" yaml:"adminAddr"`
+	ReadTimeout      Duration `json:"readTimeout" yaml:"readTimeout"`
+	WriteTimeout     Duration `json:"writeTimeout" yaml:"writeTimeout"`
+	IdleTimeout      Duration `json:"idleTimeout" yaml:"idleTimeout"`
+	PreShutdownDelay Duration `json:"preShutdownDelay" yaml:"preShutdownDelay"`
+	DrainTimeout     Duration `json:"drainTimeout" yaml:"drainTimeout"`
+	PanicThreshold   int64    `json:"panicThreshold" yaml:"panicThreshold"`
+	WatchdogSilence  Duration `json:"watchdogSilence" yaml:"watchdogSilence"`
+	LogLevel         string   `json:"logLevel" yaml:"logLevel"`
+	ServiceName      string   `json:"serviceName" yaml:"serviceName"`
+}
+
+// Defaults returns the built-in configuration used when nothing else
+// overrides it.
+func Defaults() Config {
+	return Config{
+		Addr:             "0.0.0.0:50001",
+		AdminAddr:        "0.0.0.0:9090",
+		ReadTimeout:      Duration(30 * time.Second),
+		WriteTimeout:     Duration(60 * time.Second),
+		IdleTimeout:      Duration(120 * time.Second),
+		PreShutdownDelay: Duration(5 * time.Second),
+		DrainTimeout:     Duration(30 * time.Second),
+		PanicThreshold:   10,
+		WatchdogSilence:  Duration(30 * time.Second),
+		LogLevel:         "info",
+		ServiceName:      "go-rest-template",
+	}
+}
+
+// Load builds the effective Config for args (typically os.Args[1:]),
+// layering - in increasing order of precedence - the built-in defaults, an
+// optional YAML/JSON file, environment variables, and command-line flags.
+// It validates the result and fails fast with a descriptive error if the
+// effective configuration can't plausibly run, including when a file,
+// env var, or flag value can't be parsed at all.
+func Load(args []string, getenv func(string) string) (*Config, error) {
+	cfg := Defaults()
+
+	fs := flag.NewFlagSet("go-rest-template", flag.ContinueOnError)
+	configFile := fs.String("config-file", getenv(envPrefix+"CONFIG_FILE"), "optional YAML/JSON file to load configuration from")
+	addr := fs.String("addr", "", "address the API server listens on")
+	adminAddr := fs.String("admin-addr", "", "address the admin server (metrics/pprof/configz) listens on")
+	readTimeout := fs.Duration("read-timeout", 0, "API server read timeout")
+	writeTimeout := fs.Duration("write-timeout", 0, "API server write timeout")
+	idleTimeout := fs.Duration("idle-timeout", 0, "API server idle timeout")
+	preShutdownDelay := fs.Duration("pre-shutdown-delay", 0, "time to wait after failing readiness before draining connections")
+	drainTimeout := fs.Duration("drain-timeout", 0, "maximum time to wait for in-flight requests to complete during shutdown")
+	panicThreshold := fs.Int64("panic-threshold", 0, "number of recovered panics liveness tolerates before failing")
+	watchdogSilence := fs.Duration("watchdog-silence", 0, "longest time the heartbeat goroutine may go silent before liveness fails")
+	logLevel := fs.String("log-level", "", "log level (debug, info, warn, error)")
+	serviceName := fs.String("service-name", "", "service name reported in logs and traces")
+
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+
+	if *configFile != "" {
+		if err := applyFile(&cfg, *configFile); err != nil {
+			return nil, fmt.Errorf("config: loading %s: %w", *configFile, err)
+		}
+	}
+
+	if err := applyEnv(&cfg, getenv); err != nil {
+		return nil, err
+	}
+
+	fs.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "addr":
+			cfg.Addr = *addr
+		case "admin-addr":
+			cfg.AdminAddr = *adminAddr
+		case "read-timeout":
+			cfg.ReadTimeout = Duration(*readTimeout)
+		case "write-timeout":
+			cfg.WriteTimeout = Duration(*writeTimeout)
+		case "idle-timeout":
+			cfg.IdleTimeout = Duration(*idleTimeout)
+		case "pre-shutdown-delay":
+			cfg.PreShutdownDelay = Duration(*preShutdownDelay)
+		case "drain-timeout":
+			cfg.DrainTimeout = Duration(*drainTimeout)
+		case "panic-threshold":
+			cfg.PanicThreshold = *panicThreshold
+		case "watchdog-silence":
+			cfg.WatchdogSilence = Duration(*watchdogSilence)
+		case "log-level":
+			cfg.LogLevel = *logLevel
+		case "service-name":
+			cfg.ServiceName = *serviceName
+		}
+	})
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+func applyFile(cfg *Config, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	switch filepath.Ext(path) {
+	case ".yaml", ".yml":
+		return yaml.Unmarshal(data, cfg)
+	default:
+		return json.Unmarshal(data, cfg)
+	}
+}
+
+// applyEnv overlays cfg with whatever GRT_* environment variables are set.
+// A value that's present but fails to parse is a fail-fast error, not a
+// silently-ignored default, since a typo in an env var is indistinguishable
+// from intent otherwise.
+func applyEnv(cfg *Config, getenv func(string) string) error {
+	if v := getenv(envPrefix + "ADDR"); v != "" {
+		cfg.Addr = v
+	}
+	if v := getenv(envPrefix + "ADMIN_ADDR"); v != "" {
+		cfg.AdminAddr = v
+	}
+	if v := getenv(envPrefix + "READ_TIMEOUT"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return fmt.Errorf("config: %sREAD_TIMEOUT: %w", envPrefix, err)
+		}
+		cfg.ReadTimeout = Duration(d)
+	}
+	if v := getenv(envPrefix + "WRITE_TIMEOUT"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return fmt.Errorf("config: %sWRITE_TIMEOUT: %w", envPrefix, err)
+		}
+		cfg.WriteTimeout = Duration(d)
+	}
+	if v := getenv(envPrefix + "IDLE_TIMEOUT"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return fmt.Errorf("config: %sIDLE_TIMEOUT: %w", envPrefix, err)
+		}
+		cfg.IdleTimeout = Duration(d)
+	}
+	if v := getenv(envPrefix + "PRE_SHUTDOWN_DELAY"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return fmt.Errorf("config: %sPRE_SHUTDOWN_DELAY: %w", envPrefix, err)
+		}
+		cfg.PreShutdownDelay = Duration(d)
+	}
+	if v := getenv(envPrefix + "DRAIN_TIMEOUT"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return fmt.Errorf("config: %sDRAIN_TIMEOUT: %w", envPrefix, err)
+		}
+		cfg.DrainTimeout = Duration(d)
+	}
+	if v := getenv(envPrefix + "PANIC_THRESHOLD"); v != "" {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return fmt.Errorf("config: %sPANIC_THRESHOLD: %w", envPrefix, err)
+		}
+		cfg.PanicThreshold = n
+	}
+	if v := getenv(envPrefix + "WATCHDOG_SILENCE"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return fmt.Errorf("config: %sWATCHDOG_SILENCE: %w", envPrefix, err)
+		}
+		cfg.WatchdogSilence = Duration(d)
+	}
+	if v := getenv(envPrefix + "LOG_LEVEL"); v != "" {
+		cfg.LogLevel = v
+	}
+	if v := getenv(envPrefix + "SERVICE_NAME"); v != "" {
+		cfg.ServiceName = v
+	}
+	return nil
+}
+
+// Validate fails fast if the configuration can't plausibly run, e.g. a
+// missing listen address or a non-positive timeout.
+func (c Config) Validate() error {
+	if c.Addr == "" {
+		return fmt.Errorf("config: addr must not be empty")
+	}
+	if c.AdminAddr == "" {
+		return fmt.Errorf("config: admin-addr must not be empty")
+	}
+	if c.AdminAddr == c.Addr {
+		return fmt.Errorf("config: admin-addr must differ from addr")
+	}
+	if c.ReadTimeout <= 0 || c.WriteTimeout <= 0 || c.IdleTimeout <= 0 {
+		return fmt.Errorf("config: read/write/idle timeouts must be positive")
+	}
+	if c.DrainTimeout <= 0 {
+		return fmt.Errorf("config: drain-timeout must be positive")
+	}
+	if c.PanicThreshold < 0 {
+		return fmt.Errorf("config: panic-threshold must not be negative")
+	}
+	if c.WatchdogSilence <= 0 {
+		return fmt.Errorf("config: watchdog-silence must be positive")
+	}
+	switch c.LogLevel {
+	case "debug", "info", "warn", "error":
+	default:
+		return fmt.Errorf("config: unknown log level %q", c.LogLevel)
+	}
+	return nil
+}
+
+// Handler serves the effective configuration as JSON on /configz, mirroring
+// the Kubernetes scheduler's debug endpoint of the same name, so operators
+// can see exactly what a running instance resolved its settings to.
+func (c Config) Handler() http.HandlerFunc {
+	return func(response http.ResponseWriter, request *http.Request) {
+		problem.WriteJSON(response, http.StatusOK, c)
+	}
+}
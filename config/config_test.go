@@ -0,0 +1,93 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func envLookup(env map[string]string) func(string) string {
+	return func(key string) string { return env[key] }
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	dir := t.TempDir()
+	filePath := filepath.Join(dir, "config.json")
+	fileContents := `{"addr":"file-addr:1111","logLevel":"debug","readTimeout":"45s"}`
+	if err := os.WriteFile(filePath, []byte(fileContents), 0o644); err != nil {
+		t.Fatalf("writing fixture config file: %v", err)
+	}
+
+	withFile := Defaults()
+	withFile.Addr = "file-addr:1111"
+	withFile.LogLevel = "debug"
+	withFile.ReadTimeout = Duration(45 * time.Second)
+
+	withEnv := withFile
+	withEnv.Addr = "env-addr:2222"
+
+	withFlag := withEnv
+	withFlag.Addr = "flag-addr:3333"
+
+	tests := []struct {
+		name string
+		args []string
+		env  map[string]string
+		want Config
+	}{
+		{
+			name: "defaults only",
+			want: Defaults(),
+		},
+		{
+			name: "file overrides defaults",
+			args: []string{"-config-file=" + filePath},
+			want: withFile,
+		},
+		{
+			name: "env overrides file",
+			args: []string{"-config-file=" + filePath},
+			env:  map[string]string{"GRT_ADDR": "env-addr:2222"},
+			want: withEnv,
+		},
+		{
+			name: "flag overrides env and file",
+			args: []string{"-config-file=" + filePath, "-addr=flag-addr:3333"},
+			env:  map[string]string{"GRT_ADDR": "env-addr:2222"},
+			want: withFlag,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Load(tt.args, envLookup(tt.env))
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+			if *got != tt.want {
+				t.Errorf("Load() = %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadInvalidEnvFailsFast(t *testing.T) {
+	_, err := Load(nil, envLookup(map[string]string{"GRT_READ_TIMEOUT": "30sec"}))
+	if err == nil {
+		t.Fatal("Load() error = nil, want an error for an unparsable GRT_READ_TIMEOUT")
+	}
+}
+
+func TestLoadInvalidFileFailsFast(t *testing.T) {
+	dir := t.TempDir()
+	filePath := filepath.Join(dir, "config.json")
+	if err := os.WriteFile(filePath, []byte(`not json`), 0o644); err != nil {
+		t.Fatalf("writing fixture config file: %v", err)
+	}
+
+	_, err := Load([]string{"-config-file=" + filePath}, envLookup(nil))
+	if err == nil {
+		t.Fatal("Load() error = nil, want an error for an unparsable config file")
+	}
+}
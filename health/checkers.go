@@ -0,0 +1,77 @@
+package health
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net"
+	"net/http"
+)
+
+// HTTPChecker reports unhealthy if a GET to URL fails or returns a 4xx/5xx
+// status. Client defaults to http.DefaultClient when nil.
+type HTTPChecker struct {
+	CheckerName string
+	URL         string
+	Client      *http.Client
+}
+
+// Name returns the checker's name.
+func (c *HTTPChecker) Name() string { return c.CheckerName }
+
+// Check performs the GET request.
+func (c *HTTPChecker) Check(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.URL, nil)
+	if err != nil {
+		return err
+	}
+
+	client := c.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		return fmt.Errorf("%s returned status %d", c.URL, resp.StatusCode)
+	}
+	return nil
+}
+
+// TCPChecker reports unhealthy if it can't open a TCP connection to Addr.
+type TCPChecker struct {
+	CheckerName string
+	Addr        string
+	Dialer      net.Dialer
+}
+
+// Name returns the checker's name.
+func (c *TCPChecker) Name() string { return c.CheckerName }
+
+// Check dials Addr and immediately closes the connection.
+func (c *TCPChecker) Check(ctx context.Context) error {
+	conn, err := c.Dialer.DialContext(ctx, "tcp", c.Addr)
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
+
+// SQLChecker reports unhealthy if DB doesn't respond to a ping.
+type SQLChecker struct {
+	CheckerName string
+	DB          *sql.DB
+}
+
+// Name returns the checker's name.
+func (c *SQLChecker) Name() string { return c.CheckerName }
+
+// Check pings the database.
+func (c *SQLChecker) Check(ctx context.Context) error {
+	return c.DB.PingContext(ctx)
+}
@@ -0,0 +1,178 @@
+// Package health provides pluggable readiness and liveness checks for
+// container orchestrators such as Kubernetes (see the probe lifecycle
+// described in the package comment in server.go).
+//
+// Readiness aggregates every registered dependency Checker - things like a
+// database ping or a downstream HTTP call - so the orchestrator stops
+// routing traffic as soon as any of them fails. Liveness is deliberately
+// narrower: it should only fail on process-fatal conditions that warrant a
+// restart, such as a detected deadlock or a runaway panic rate.
+package health
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/TonyJCaruana/go-rest-template/middleware"
+	"github.com/TonyJCaruana/go-rest-template/problem"
+)
+
+// Checker is a single dependency or process health probe.
+type Checker interface {
+	Name() string
+	Check(ctx context.Context) error
+}
+
+// CheckerFunc adapts a plain function to the Checker interface.
+type CheckerFunc struct {
+	CheckerName string
+	Fn          func(ctx context.Context) error
+}
+
+// Name returns the checker's name.
+func (c CheckerFunc) Name() string { return c.CheckerName }
+
+// Check runs the wrapped function.
+func (c CheckerFunc) Check(ctx context.Context) error { return c.Fn(ctx) }
+
+// Status is the outcome of running a single Checker.
+type Status struct {
+	Name  string `json:"name"`
+	Error string `json:"error,omitempty"`
+}
+
+type entry struct {
+	checker Checker
+	timeout time.Duration
+}
+
+type report struct {
+	Status string   `json:"status"`
+	Checks []Status `json:"checks"`
+}
+
+// Registry holds the set of checkers consulted by the readiness and
+// liveness handlers. The zero value is an empty, ready-to-use registry;
+// NewRegistry returns a pointer to one.
+type Registry struct {
+	mu       sync.RWMutex
+	ready    []entry
+	live     []entry
+	draining bool
+}
+
+// NewRegistry creates an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// RegisterReady adds a dependency check consulted by the readiness probe.
+// The check is given timeout to complete before it's considered failed.
+func (r *Registry) RegisterReady(c Checker, timeout time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.ready = append(r.ready, entry{checker: c, timeout: timeout})
+}
+
+// RegisterLive adds a process-health check consulted by the liveness
+// probe. The check is given timeout to complete before it's considered
+// failed.
+func (r *Registry) RegisterLive(c Checker, timeout time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.live = append(r.live, entry{checker: c, timeout: timeout})
+}
+
+// Drain marks the service as shutting down: the readiness handler will
+// immediately report unready, regardless of what the dependency checks
+// say, so the orchestrator stops routing new traffic while in-flight
+// requests finish.
+func (r *Registry) Drain() {
+	r.mu.Lock()
+	r.draining = true
+	r.mu.Unlock()
+}
+
+// ReadinessHandler reports whether the service is ready to receive
+// traffic: unready while draining, otherwise the aggregate of every
+// registered ready check.
+func (r *Registry) ReadinessHandler() http.HandlerFunc {
+	return func(response http.ResponseWriter, request *http.Request) {
+		r.mu.RLock()
+		draining := r.draining
+		entries := append([]entry(nil), r.ready...)
+		r.mu.RUnlock()
+
+		if draining {
+			problem.WriteProblem(response, http.StatusServiceUnavailable, &problem.Detail{
+				Type:     "http://example.org/error/503",
+				Title:    "Service is shutting down",
+				Status:   http.StatusServiceUnavailable,
+				Detail:   "server is draining in-flight requests before exit",
+				Instance: middleware.InstanceURI(request.Context()),
+			})
+			return
+		}
+
+		writeReport(response, request.Context(), runChecks(request.Context(), entries))
+	}
+}
+
+// LivenessHandler reports whether the process itself is healthy. Unlike
+// readiness it ignores the draining flag - a service that is gracefully
+// shutting down is still alive - and only fails on the process-fatal
+// conditions registered via RegisterLive.
+func (r *Registry) LivenessHandler() http.HandlerFunc {
+	return func(response http.ResponseWriter, request *http.Request) {
+		r.mu.RLock()
+		entries := append([]entry(nil), r.live...)
+		r.mu.RUnlock()
+
+		writeReport(response, request.Context(), runChecks(request.Context(), entries))
+	}
+}
+
+func runChecks(ctx context.Context, entries []entry) []Status {
+	results := make([]Status, len(entries))
+	var wg sync.WaitGroup
+	for i, e := range entries {
+		wg.Add(1)
+		go func(i int, e entry) {
+			defer wg.Done()
+			checkCtx, cancel := context.WithTimeout(ctx, e.timeout)
+			defer cancel()
+			status := Status{Name: e.checker.Name()}
+			if err := e.checker.Check(checkCtx); err != nil {
+				status.Error = err.Error()
+			}
+			results[i] = status
+		}(i, e)
+	}
+	wg.Wait()
+	return results
+}
+
+func writeReport(response http.ResponseWriter, ctx context.Context, results []Status) {
+	var failed []string
+	for _, s := range results {
+		if s.Error != "" {
+			failed = append(failed, s.Name)
+		}
+	}
+
+	if len(failed) == 0 {
+		problem.WriteJSON(response, http.StatusOK, report{Status: "UP", Checks: results})
+		return
+	}
+
+	problem.WriteProblem(response, http.StatusServiceUnavailable, &problem.Detail{
+		Type:     "http://example.org/error/503",
+		Title:    "Dependency check failed",
+		Status:   http.StatusServiceUnavailable,
+		Detail:   fmt.Sprintf("failed checks: %v", failed),
+		Instance: middleware.InstanceURI(ctx),
+	})
+}
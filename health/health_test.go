@@ -0,0 +1,91 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestReadinessHandler(t *testing.T) {
+	t.Run("UP with no checks registered", func(t *testing.T) {
+		registry := NewRegistry()
+
+		rec := httptest.NewRecorder()
+		registry.ReadinessHandler()(rec, httptest.NewRequest(http.MethodGet, "/ready", nil))
+
+		if rec.Code != http.StatusOK {
+			t.Errorf("status = %d, want %d", rec.Code, http.StatusOK)
+		}
+	})
+
+	t.Run("one check failing returns 503", func(t *testing.T) {
+		registry := NewRegistry()
+		registry.RegisterReady(CheckerFunc{CheckerName: "db", Fn: func(context.Context) error {
+			return errors.New("connection refused")
+		}}, time.Second)
+
+		rec := httptest.NewRecorder()
+		registry.ReadinessHandler()(rec, httptest.NewRequest(http.MethodGet, "/ready", nil))
+
+		if rec.Code != http.StatusServiceUnavailable {
+			t.Errorf("status = %d, want %d", rec.Code, http.StatusServiceUnavailable)
+		}
+	})
+
+	t.Run("draining returns 503 even with passing checks", func(t *testing.T) {
+		registry := NewRegistry()
+		registry.RegisterReady(CheckerFunc{CheckerName: "db", Fn: func(context.Context) error {
+			return nil
+		}}, time.Second)
+		registry.Drain()
+
+		rec := httptest.NewRecorder()
+		registry.ReadinessHandler()(rec, httptest.NewRequest(http.MethodGet, "/ready", nil))
+
+		if rec.Code != http.StatusServiceUnavailable {
+			t.Errorf("status = %d, want %d", rec.Code, http.StatusServiceUnavailable)
+		}
+	})
+}
+
+func TestLivenessHandler(t *testing.T) {
+	t.Run("UP with no checks registered", func(t *testing.T) {
+		registry := NewRegistry()
+
+		rec := httptest.NewRecorder()
+		registry.LivenessHandler()(rec, httptest.NewRequest(http.MethodGet, "/live", nil))
+
+		if rec.Code != http.StatusOK {
+			t.Errorf("status = %d, want %d", rec.Code, http.StatusOK)
+		}
+	})
+
+	t.Run("failing check returns 503", func(t *testing.T) {
+		registry := NewRegistry()
+		registry.RegisterLive(CheckerFunc{CheckerName: "watchdog", Fn: func(context.Context) error {
+			return errors.New("no heartbeat")
+		}}, time.Second)
+
+		rec := httptest.NewRecorder()
+		registry.LivenessHandler()(rec, httptest.NewRequest(http.MethodGet, "/live", nil))
+
+		if rec.Code != http.StatusServiceUnavailable {
+			t.Errorf("status = %d, want %d", rec.Code, http.StatusServiceUnavailable)
+		}
+	})
+
+	t.Run("ignores draining", func(t *testing.T) {
+		registry := NewRegistry()
+		registry.Drain()
+
+		rec := httptest.NewRecorder()
+		registry.LivenessHandler()(rec, httptest.NewRequest(http.MethodGet, "/live", nil))
+
+		if rec.Code != http.StatusOK {
+			t.Errorf("status = %d, want %d, liveness must ignore the draining flag", rec.Code, http.StatusOK)
+		}
+	})
+}
@@ -0,0 +1,63 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/TonyJCaruana/go-rest-template/metrics"
+)
+
+type panicChecker struct {
+	threshold int64
+}
+
+func (c *panicChecker) Name() string { return "panics" }
+
+func (c *panicChecker) Check(ctx context.Context) error {
+	if n := metrics.PanicsTotal(); n > c.threshold {
+		return fmt.Errorf("observed %d recovered panics, exceeding threshold %d", n, c.threshold)
+	}
+	return nil
+}
+
+// NewPanicChecker builds a liveness Checker that fails once more than
+// threshold panics have been recovered by the panic-recovery middleware.
+func NewPanicChecker(threshold int64) Checker {
+	return &panicChecker{threshold: threshold}
+}
+
+// Watchdog is a simple deadlock detector: some background goroutine is
+// expected to call Kick periodically, and the liveness check fails once
+// more than maxSilence has passed without a Kick, on the assumption that
+// the goroutine is wedged.
+type Watchdog struct {
+	maxSilence time.Duration
+	lastKick   atomic.Int64
+}
+
+// NewWatchdog creates a Watchdog that expects a Kick at least every
+// maxSilence.
+func NewWatchdog(maxSilence time.Duration) *Watchdog {
+	w := &Watchdog{maxSilence: maxSilence}
+	w.Kick()
+	return w
+}
+
+// Kick records that the monitored goroutine is still making progress.
+func (w *Watchdog) Kick() {
+	w.lastKick.Store(time.Now().UnixNano())
+}
+
+// Name returns the checker's name.
+func (w *Watchdog) Name() string { return "watchdog" }
+
+// Check fails if Kick hasn't been called within maxSilence.
+func (w *Watchdog) Check(ctx context.Context) error {
+	silence := time.Since(time.Unix(0, w.lastKick.Load()))
+	if silence > w.maxSilence {
+		return fmt.Errorf("no heartbeat for %s, possible deadlock", silence)
+	}
+	return nil
+}
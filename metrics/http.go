@@ -0,0 +1,62 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// RED metrics for HTTP routes, labeled by route (not raw path, to keep
+// cardinality bounded) and response status.
+var (
+	requestsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "http_requests_total",
+		Help: "Total number of HTTP requests handled, by route and status.",
+	}, []string{"route", "status"})
+
+	requestErrorsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "http_request_errors_total",
+		Help: "Total number of HTTP requests that returned a 4xx/5xx status, by route and status.",
+	}, []string{"route", "status"})
+
+	requestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "http_request_duration_seconds",
+		Help:    "HTTP request latency in seconds, by route and status.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"route", "status"})
+)
+
+// InstrumentRoute wraps next with RED (rate/errors/duration) metrics,
+// labeling every observation with route - a caller-supplied identifier
+// such as "/{id}" rather than the raw request path, so cardinality stays
+// bounded regardless of how many distinct IDs are requested.
+func InstrumentRoute(route string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: response, status: http.StatusOK}
+
+		next.ServeHTTP(rec, request)
+
+		status := strconv.Itoa(rec.status)
+		requestsTotal.WithLabelValues(route, status).Inc()
+		if rec.status >= 400 {
+			requestErrorsTotal.WithLabelValues(route, status).Inc()
+		}
+		requestDuration.WithLabelValues(route, status).Observe(time.Since(start).Seconds())
+	})
+}
+
+// statusRecorder captures the status code written by the wrapped handler
+// so it can be used as a metric label after the fact.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
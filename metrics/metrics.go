@@ -0,0 +1,37 @@
+// Package metrics holds the process-wide counters this service exposes
+// about its own health and HTTP traffic, registered as Prometheus
+// collectors with the default registerer.
+package metrics
+
+import (
+	"sync/atomic"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// panicsTotal is the source of truth used by health.NewPanicChecker, kept
+// as a plain atomic counter so liveness can read it without depending on
+// the Prometheus client. panicsTotalMetric mirrors the same count as a
+// real Prometheus collector so it's actually scrapable on /metrics,
+// registered against the default registerer that promhttp.Handler serves.
+var (
+	panicsTotal int64
+
+	panicsTotalMetric = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "panics_total",
+		Help: "Total number of panics recovered by the service.",
+	})
+)
+
+// IncPanics increments panics_total. It's called by the panic-recovery
+// middleware and the SafeGo helper whenever a panic is recovered.
+func IncPanics() {
+	atomic.AddInt64(&panicsTotal, 1)
+	panicsTotalMetric.Inc()
+}
+
+// PanicsTotal returns the number of panics recovered so far.
+func PanicsTotal() int64 {
+	return atomic.LoadInt64(&panicsTotal)
+}
@@ -0,0 +1,49 @@
+package middleware
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// Logging returns middleware that emits one structured log line per
+// request - method, path, status, bytes written, duration, and the
+// request's correlation id - via logger.
+func Logging(logger *slog.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+			start := time.Now()
+			rec := &responseRecorder{ResponseWriter: response, status: http.StatusOK}
+
+			next.ServeHTTP(rec, request)
+
+			logger.Info("request",
+				"method", request.Method,
+				"path", request.URL.Path,
+				"status", rec.status,
+				"bytes", rec.bytes,
+				"duration", time.Since(start),
+				"request_id", RequestIDFromContext(request.Context()),
+			)
+		})
+	}
+}
+
+// responseRecorder captures the status and byte count written by a
+// handler so Logging can report them after the fact.
+type responseRecorder struct {
+	http.ResponseWriter
+	status int
+	bytes  int
+}
+
+func (r *responseRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	n, err := r.ResponseWriter.Write(b)
+	r.bytes += n
+	return n, err
+}
@@ -0,0 +1,64 @@
+// Package middleware holds cross-cutting HTTP middleware and goroutine
+// helpers shared across the service's handlers.
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"runtime/debug"
+
+	"github.com/TonyJCaruana/go-rest-template/metrics"
+	"github.com/TonyJCaruana/go-rest-template/problem"
+)
+
+// PanicHandler, if set, is called with the recovered panic value whenever
+// Recover or SafeGo catches one - e.g. to forward it to Sentry or an otel
+// error reporter. It runs after the panic has already been counted and
+// logged, so it's safe to leave nil.
+var PanicHandler func(interface{})
+
+// Recover wraps next so that a panic inside it is turned into a 500
+// application/problem+json response instead of crashing the process. The
+// panic is logged, counted in panics_total, and forwarded to PanicHandler
+// if one is set.
+func Recover(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+		defer func() {
+			if rec := recover(); rec != nil {
+				handleRecovered(rec)
+				problem.WriteProblem(response, http.StatusInternalServerError, &problem.Detail{
+					Type:     "http://example.org/error/500",
+					Title:    "Internal Server Error",
+					Status:   http.StatusInternalServerError,
+					Detail:   "the server encountered an unexpected condition and recovered",
+					Instance: InstanceURI(request.Context()),
+				})
+			}
+		}()
+		next.ServeHTTP(response, request)
+	})
+}
+
+// SafeGo runs fn in its own goroutine with a recover guard, so a panic in
+// fn can't take down the whole process. This mirrors Kubernetes'
+// util.HandleCrash and should wrap every long-lived background goroutine
+// (signal listeners, heartbeats, workers).
+func SafeGo(fn func()) {
+	go func() {
+		defer func() {
+			if rec := recover(); rec != nil {
+				handleRecovered(rec)
+			}
+		}()
+		fn()
+	}()
+}
+
+func handleRecovered(rec interface{}) {
+	fmt.Fprintf(os.Stderr, "panic recovered: %v\n%s", rec, debug.Stack())
+	metrics.IncPanics()
+	if PanicHandler != nil {
+		PanicHandler(rec)
+	}
+}
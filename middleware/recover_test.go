@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/TonyJCaruana/go-rest-template/metrics"
+)
+
+func TestRecoverCatchesPanicAndReportsProblem(t *testing.T) {
+	before := metrics.PanicsTotal()
+
+	handler := Recover(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		panic("boom")
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusInternalServerError)
+	}
+	if ct := rec.Header().Get("content-type"); ct != "application/problem+json;charset=utf-8" {
+		t.Errorf("content-type = %q, want application/problem+json;charset=utf-8", ct)
+	}
+	if got := metrics.PanicsTotal(); got != before+1 {
+		t.Errorf("PanicsTotal() = %d, want %d", got, before+1)
+	}
+}
+
+func TestRecoverPassesThroughNonPanickingRequests(t *testing.T) {
+	handler := Recover(http.HandlerFunc(func(response http.ResponseWriter, _ *http.Request) {
+		response.WriteHeader(http.StatusTeapot)
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if rec.Code != http.StatusTeapot {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusTeapot)
+	}
+}
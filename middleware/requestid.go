@@ -0,0 +1,71 @@
+package middleware
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"strings"
+)
+
+type contextKey string
+
+const requestIDKey contextKey = "requestID"
+
+// RequestID extracts a correlation id from the X-Request-ID or
+// traceparent header, generating one if neither is present, and stores it
+// in the request context under requestIDKey. The id is echoed back on
+// X-Request-ID so a caller that didn't supply one can still correlate
+// their request with server-side logs.
+func RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+		id := request.Header.Get("X-Request-ID")
+		if id == "" {
+			id = traceIDFromTraceparent(request.Header.Get("traceparent"))
+		}
+		if id == "" {
+			id = newRequestID()
+		}
+
+		response.Header().Set("X-Request-ID", id)
+		ctx := context.WithValue(request.Context(), requestIDKey, id)
+		next.ServeHTTP(response, request.WithContext(ctx))
+	})
+}
+
+// RequestIDFromContext returns the correlation id stored by RequestID, or
+// "" if ctx doesn't carry one.
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey).(string)
+	return id
+}
+
+// InstanceURI builds the RFC7807 Instance URI for a problem response,
+// pointing at the request's correlation id so operators can grep logs for
+// it from a failed response alone.
+func InstanceURI(ctx context.Context) string {
+	id := RequestIDFromContext(ctx)
+	if id == "" {
+		return "http://example.org/myservice/error"
+	}
+	return "http://example.org/myservice/requests/" + id
+}
+
+func newRequestID() string {
+	var b [16]byte
+	// crypto/rand.Read on the standard reader never returns an error.
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+// traceIDFromTraceparent pulls the trace-id out of a W3C traceparent
+// header ("version-traceid-spanid-flags"). It returns the second
+// dash-separated field if that field is 32 characters long, and "" otherwise;
+// the other fields, and whether the trace-id is hex, are not checked.
+func traceIDFromTraceparent(traceparent string) string {
+	parts := strings.Split(traceparent, "-")
+	if len(parts) >= 2 && len(parts[1]) == 32 {
+		return parts[1]
+	}
+	return ""
+}
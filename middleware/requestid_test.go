@@ -0,0 +1,50 @@
+package middleware
+
+import "testing"
+
+func TestTraceIDFromTraceparent(t *testing.T) {
+	tests := []struct {
+		name        string
+		traceparent string
+		want        string
+	}{
+		{
+			name:        "valid traceparent",
+			traceparent: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+			want:        "4bf92f3577b34da6a3ce929d0e0e4736",
+		},
+		{
+			name:        "empty header",
+			traceparent: "",
+			want:        "",
+		},
+		{
+			name:        "exactly two fields still valid",
+			traceparent: "00-4bf92f3577b34da6a3ce929d0e0e4736",
+			want:        "4bf92f3577b34da6a3ce929d0e0e4736",
+		},
+		{
+			name:        "malformed header with no real trace-id",
+			traceparent: "not-a-traceparent",
+			want:        "",
+		},
+		{
+			name:        "trace-id too short",
+			traceparent: "00-bad-00f067aa0ba902b7-01",
+			want:        "",
+		},
+		{
+			name:        "only a version field",
+			traceparent: "00",
+			want:        "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := traceIDFromTraceparent(tt.traceparent); got != tt.want {
+				t.Errorf("traceIDFromTraceparent(%q) = %q, want %q", tt.traceparent, got, tt.want)
+			}
+		})
+	}
+}
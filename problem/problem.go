@@ -0,0 +1,44 @@
+// Package problem implements the document format described by RFC7807
+// (https://tools.ietf.org/html/rfc7807). It is shared by every package in
+// this service that needs to report a failure back through the API, so
+// that a caller always sees the same shape regardless of which subsystem
+// produced the error.
+package problem
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// Detail is the RFC7807 problem document.
+type Detail struct {
+	Type     string `json:"type"`
+	Title    string `json:"title"`
+	Status   int    `json:"status"`
+	Detail   string `json:"detail"`
+	Instance string `json:"instance"`
+}
+
+// WriteProblem writes d as an application/problem+json document with the
+// given status code.
+func WriteProblem(response http.ResponseWriter, status int, d *Detail) {
+	writeHeaders(response, status, "application/problem+json")
+	json.NewEncoder(response).Encode(d)
+}
+
+// WriteJSON writes v as a plain application/json document with the given
+// status code.
+func WriteJSON(response http.ResponseWriter, status int, v interface{}) {
+	writeHeaders(response, status, "application/json")
+	json.NewEncoder(response).Encode(v)
+}
+
+func writeHeaders(response http.ResponseWriter, status int, contentType string) {
+	response.Header().Set("content-type", contentType+";charset=utf-8")
+	response.Header().Set("Content-Language", "en")
+	response.Header().Set("Cache-Control", "no-cache")
+	response.Header().Set("Pragma", "no-cache")
+	response.Header().Set("Expires", "-1")
+
+	response.WriteHeader(status)
+}
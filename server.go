@@ -1,140 +1,267 @@
-/*This service is intended to be deployed in a container such as Docker and run on a server orchestration framework.
-  Here’s what a potential real life request processing failure scenario might look like
-
-   - Readiness probe fails
-   - Kubernetes stops routing traffic to the pod.
-   - Liveness probe fails.
-   - Kubernetes restarts the failed container*.
-   - Readiness probe succeeds.
-   - Kubernetes starts routing traffic to the pod again.
-
-  Author: Anthony Caruana
-*/
-package main
-
-import (
-	"context"
-	"encoding/json"
-	"errors"
-	"fmt"
-	"math/rand"
-	"net/http"
-	"os"
-	"os/signal"
-	"time"
-
-	"github.com/gorilla/mux"
-)
-
-var (
-	addr = "0.0.0.0:50001"
-	port = 50001
-)
-
-// Problem detail as defined in RFC7807 specification ( https://tools.ietf.org/html/rfc7807 )
-type problemDetail struct {
-	Type     string `json:"type"`
-	Title    string `json:"title"`
-	Status   int    `json:"status"`
-	Detail   string `json:"detail"`
-	Instance string `json:"instance"`
-}
-
-func main() {
-
-	// subcribe to SIGINT/SIGKILL
-	osSignalChannel := make(chan os.Signal)
-	signal.Notify(osSignalChannel, os.Interrupt, os.Kill)
-
-	// set up handler and ready/live probes for orchestration framework
-	router := mux.NewRouter()
-	router.HandleFunc("/{id}", requestHandler)
-	router.HandleFunc("/live", livenessProbe)
-	router.HandleFunc("/ready", readinessProbe)
-
-	fmt.Printf("\n>> Server running on [%d]\n", port)
-	fmt.Println("   Press <Ctr-C> to quit...")
-
-	// configure timeouts/address/handler
-	srv := &http.Server{
-		ReadTimeout:  30 * time.Second,
-		WriteTimeout: 60 * time.Second,
-		IdleTimeout:  120 * time.Second,
-		Addr:         addr,
-		Handler:      router,
-	}
-
-	// launch http server
-	go func() {
-		srv.ListenAndServe()
-	}()
-
-	// listen for SIGINT/SIGKILL
-	<-osSignalChannel
-	fmt.Println("   Server shutting down...")
-
-	// shut down server gracefully
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-	defer srv.Shutdown(ctx)
-	fmt.Println(">> Server stopped")
-	fmt.Println("")
-}
-
-func performRequest(id string) (body string, status int, err error) {
-
-	// TODO! - Write logic here to perform service function and return either result or error in response
-	msg := "Service running!"
-
-	if rand.Intn(10)%2 == 0 {
-		// We have a problem so generate a problem detail ( N.B Depending on the issue you may return any 400 - 500 status to provide addtional information)
-		problem := &problemDetail{Type: "http://example.org/error/500", Title: "The service is currently un-available", Status: http.StatusInternalServerError, Detail: "Unable to resolve DNS hostname MyService", Instance: "http://example.org/myservice/error/500"}
-		document, _ := json.Marshal(problem)
-		return string(document), http.StatusInternalServerError, errors.New("Service un-available!")
-	}
-	// All is OK so just return the response to the caller
-	return "{ \"ID\" : \"" + id + "\", \"Message\" : \"" + msg + "\", \"Status\" : \"" + http.StatusText(http.StatusOK) + "\" }", http.StatusOK, nil
-
-}
-
-func readinessProbe(response http.ResponseWriter, request *http.Request) {
-
-	// Tells container orchestrator such as Mesos/Marathon OR Kubernetes or discovery system such as Consul OR ZooKeeper
-	// that we are avaialable to serve traffic, and that can communicate with downstream services such as databases or queues
-
-	// TODO! - Write logic here to determine application readiness for your service
-	writeStandardHeaders(response, http.StatusOK, "application/json")
-}
-
-func requestHandler(response http.ResponseWriter, request *http.Request) {
-
-	id := mux.Vars(request)["id"]
-
-	if body, status, err := performRequest(id); err != nil {
-		writeStandardHeaders(response, status, "application/problem+json")
-		fmt.Fprintf(response, body)
-	} else {
-		writeStandardHeaders(response, status, "application/json")
-		fmt.Fprintf(response, body)
-	}
-
-}
-
-func livenessProbe(response http.ResponseWriter, request *http.Request) {
-
-	// Tells container orchestrator such as Mesos/Marathon OR Kubernetes or discovery system such as Consul OR ZooKeeper
-	// that we are still alive, haven't crashed, and don't need to be re-started. Equivalant to a HTTP Ping
-	writeStandardHeaders(response, http.StatusOK, "application/json")
-}
-
-func writeStandardHeaders(response http.ResponseWriter, status int, contentType string) {
-
-	// set common headers and response code
-	response.Header().Set("content-type", contentType+";charset=utf-8")
-	response.Header().Set("Content-Language", "en")
-	response.Header().Set("Cache-Control", "no-cache")
-	response.Header().Set("Pragma", "no-cache")
-	response.Header().Set("Expires", "-1")
-
-	response.WriteHeader(status)
-}
+/*This service is intended to be deployed in a container such as Docker and run on a server orchestration framework.
+  Here’s what a potential real life request processing failure scenario might look like
+
+   - Readiness probe fails
+   - Kubernetes stops routing traffic to the pod.
+   - Liveness probe fails.
+   - Kubernetes restarts the failed container*.
+   - Readiness probe succeeds.
+   - Kubernetes starts routing traffic to the pod again.
+
+  Author: Anthony Caruana
+*/
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/TonyJCaruana/go-rest-template/config"
+	"github.com/TonyJCaruana/go-rest-template/health"
+	"github.com/TonyJCaruana/go-rest-template/metrics"
+	"github.com/TonyJCaruana/go-rest-template/middleware"
+	"github.com/TonyJCaruana/go-rest-template/problem"
+)
+
+var (
+	cfg *config.Config
+
+	healthRegistry = health.NewRegistry()
+	watchdog       *health.Watchdog
+
+	// tracer emits spans around request handling. Wire an actual
+	// TracerProvider via otel.SetTracerProvider in main for a real
+	// deployment; without one this is a harmless no-op.
+	tracer = otel.Tracer("github.com/TonyJCaruana/go-rest-template")
+
+	logger *slog.Logger
+)
+
+func main() {
+	var err error
+	cfg, err = config.Load(os.Args[1:], os.Getenv)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, ">> invalid configuration: %v\n", err)
+		os.Exit(1)
+	}
+
+	logger = slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: logLevel(cfg.LogLevel)})).With("service", cfg.ServiceName)
+
+	watchdog = health.NewWatchdog(time.Duration(cfg.WatchdogSilence))
+	healthRegistry.RegisterLive(health.NewPanicChecker(cfg.PanicThreshold), time.Second)
+	healthRegistry.RegisterLive(watchdog, time.Second)
+	// TODO! - RegisterReady dependency checks for your downstream services,
+	// e.g. healthRegistry.RegisterReady(&health.SQLChecker{CheckerName: "db", DB: db}, 2*time.Second)
+
+	middleware.SafeGo(runWatchdogHeartbeat)
+
+	// subscribe to SIGINT/SIGTERM - SIGKILL can't be trapped, and SIGTERM is
+	// what Kubernetes sends to a pod before it forcibly kills the container.
+	osSignalChannel := make(chan os.Signal, 1)
+	signal.Notify(osSignalChannel, os.Interrupt, syscall.SIGTERM)
+
+	// set up handler and ready/live probes for orchestration framework
+	router := newRouter(healthRegistry)
+
+	// configure timeouts/address/handler. RequestID runs outermost so the
+	// correlation id is already on the request context by the time Logging
+	// sees it. Recover is wired in per-route, innermost to each handler, so
+	// Logging and InstrumentRoute still observe a panicking request's final
+	// status instead of losing it to the unwind.
+	srv := &http.Server{
+		ReadTimeout:  time.Duration(cfg.ReadTimeout),
+		WriteTimeout: time.Duration(cfg.WriteTimeout),
+		IdleTimeout:  time.Duration(cfg.IdleTimeout),
+		Addr:         cfg.Addr,
+		Handler:      middleware.RequestID(middleware.Logging(logger)(router)),
+	}
+
+	// serverCtx is cancelled the moment we start shutting down, so that
+	// handlers in flight can observe it via request.Context() and abort
+	// any work that's no longer worth finishing.
+	serverCtx, cancelServerCtx := context.WithCancel(context.Background())
+	srv.BaseContext = func(net.Listener) context.Context { return serverCtx }
+
+	// admin mux carries Prometheus scraping, pprof profiling, and the
+	// effective config, isolated on their own port so they never queue
+	// behind real API traffic.
+	adminRouter := http.NewServeMux()
+	adminRouter.Handle("/metrics", promhttp.Handler())
+	adminRouter.HandleFunc("/debug/pprof/", pprof.Index)
+	adminRouter.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	adminRouter.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	adminRouter.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	adminRouter.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	adminRouter.HandleFunc("/configz", cfg.Handler())
+
+	adminSrv := &http.Server{
+		Addr:    cfg.AdminAddr,
+		Handler: adminRouter,
+	}
+
+	logger.Info("server starting", "addr", cfg.Addr)
+	logger.Info("admin server starting", "addr", cfg.AdminAddr)
+
+	// run the API server, the admin server, and the shutdown sequence
+	// together: if any one of them fails, the others are torn down too.
+	g, gCtx := errgroup.WithContext(context.Background())
+
+	g.Go(func() error {
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			return fmt.Errorf("api server: %w", err)
+		}
+		return nil
+	})
+
+	g.Go(func() error {
+		if err := adminSrv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			return fmt.Errorf("admin server: %w", err)
+		}
+		return nil
+	})
+
+	g.Go(func() error {
+		select {
+		case <-osSignalChannel:
+			logger.Info("server shutting down", "reason", "signal")
+		case <-gCtx.Done():
+			logger.Info("server shutting down", "reason", "peer failure")
+		}
+
+		// flip readiness first so the orchestrator stops routing new
+		// traffic, then give it preShutdownDelay to notice before we
+		// touch the listener.
+		healthRegistry.Drain()
+		time.Sleep(time.Duration(cfg.PreShutdownDelay))
+
+		cancelServerCtx()
+
+		// drain in-flight requests, bounded by drainTimeout
+		shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), time.Duration(cfg.DrainTimeout))
+		defer cancelShutdown()
+		if err := srv.Shutdown(shutdownCtx); err != nil {
+			logger.Error("api server did not shut down cleanly", "error", err)
+		}
+		if err := adminSrv.Shutdown(shutdownCtx); err != nil {
+			logger.Error("admin server did not shut down cleanly", "error", err)
+		}
+		return nil
+	})
+
+	if err := g.Wait(); err != nil {
+		logger.Error("server stopped with error", "error", err)
+	}
+	logger.Info("server stopped")
+}
+
+// newRouter builds the API router against registry. /live and /ready are
+// registered before the /{id} catch-all: mux dispatches to the first
+// registered route that matches, and an unconstrained /{id} matches
+// "/live" and "/ready" too, so registering it first would silently
+// swallow both probes - including the readiness handler's 503 while
+// registry is draining.
+func newRouter(registry *health.Registry) *mux.Router {
+	router := mux.NewRouter()
+	router.Handle("/live", middleware.Recover(registry.LivenessHandler()))
+	router.Handle("/ready", middleware.Recover(registry.ReadinessHandler()))
+	router.Handle("/{id}", metrics.InstrumentRoute("/{id}", middleware.Recover(http.HandlerFunc(requestHandler))))
+	return router
+}
+
+// logLevel maps the configured log level name to a slog.Level, defaulting
+// to info for anything config.Validate didn't already reject.
+func logLevel(level string) slog.Level {
+	switch level {
+	case "debug":
+		return slog.LevelDebug
+	case "warn":
+		return slog.LevelWarn
+	case "error":
+		return slog.LevelError
+	default:
+		return slog.LevelInfo
+	}
+}
+
+// runWatchdogHeartbeat kicks the liveness watchdog periodically. If this
+// goroutine ever wedges, the watchdog goes silent and liveness starts
+// failing so the orchestrator restarts us.
+func runWatchdogHeartbeat() {
+	ticker := time.NewTicker(time.Duration(cfg.WatchdogSilence) / 3)
+	defer ticker.Stop()
+	for range ticker.C {
+		watchdog.Kick()
+	}
+}
+
+func performRequest(ctx context.Context, id string) (body string, status int, err error) {
+
+	// bail out early if the request has already been cancelled, e.g. because
+	// the server is draining and the client went away
+	if err := ctx.Err(); err != nil {
+		p := &problem.Detail{Type: "http://example.org/error/503", Title: "Request aborted", Status: http.StatusServiceUnavailable, Detail: err.Error(), Instance: middleware.InstanceURI(ctx)}
+		document, _ := json.Marshal(p)
+		return string(document), http.StatusServiceUnavailable, err
+	}
+
+	// TODO! - Write logic here to perform service function and return either result or error in response
+	msg := "Service running!"
+
+	if rand.Intn(10)%2 == 0 {
+		// We have a problem so generate a problem detail ( N.B Depending on the issue you may return any 400 - 500 status to provide addtional information)
+		p := &problem.Detail{Type: "http://example.org/error/500", Title: "The service is currently un-available", Status: http.StatusInternalServerError, Detail: "Unable to resolve DNS hostname MyService", Instance: middleware.InstanceURI(ctx)}
+		document, _ := json.Marshal(p)
+		return string(document), http.StatusInternalServerError, errors.New("Service un-available!")
+	}
+	// All is OK so just return the response to the caller
+	return "{ \"ID\" : \"" + id + "\", \"Message\" : \"" + msg + "\", \"Status\" : \"" + http.StatusText(http.StatusOK) + "\" }", http.StatusOK, nil
+
+}
+
+func requestHandler(response http.ResponseWriter, request *http.Request) {
+
+	id := mux.Vars(request)["id"]
+
+	ctx, span := tracer.Start(request.Context(), "performRequest", trace.WithAttributes(attribute.String("id", id)))
+	defer span.End()
+
+	if body, status, err := performRequest(ctx, id); err != nil {
+		span.RecordError(err)
+		writeStandardHeaders(response, status, "application/problem+json")
+		fmt.Fprintf(response, body)
+	} else {
+		writeStandardHeaders(response, status, "application/json")
+		fmt.Fprintf(response, body)
+	}
+
+}
+
+func writeStandardHeaders(response http.ResponseWriter, status int, contentType string) {
+
+	// set common headers and response code
+	response.Header().Set("content-type", contentType+";charset=utf-8")
+	response.Header().Set("Content-Language", "en")
+	response.Header().Set("Cache-Control", "no-cache")
+	response.Header().Set("Pragma", "no-cache")
+	response.Header().Set("Expires", "-1")
+
+	response.WriteHeader(status)
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/TonyJCaruana/go-rest-template/health"
+)
+
+func TestReadyRouteReflectsDrain(t *testing.T) {
+	registry := health.NewRegistry()
+	router := newRouter(registry)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/ready", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET /ready before Drain() = %d, want %d", rec.Code, http.StatusOK)
+	}
+
+	registry.Drain()
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/ready", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("GET /ready after Drain() = %d, want %d", rec.Code, http.StatusServiceUnavailable)
+	}
+}
+
+func TestLiveAndReadyAreNotSwallowedByCatchAll(t *testing.T) {
+	registry := health.NewRegistry()
+	router := newRouter(registry)
+
+	for _, path := range []string{"/live", "/ready"} {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		if rec.Code != http.StatusOK {
+			t.Errorf("GET %s = %d, want %d (should reach the health handler, not requestHandler)", path, rec.Code, http.StatusOK)
+		}
+		// requestHandler never writes "status":"UP" (its success body uses a
+		// capitalised "Status" key and its problem documents carry a numeric
+		// status), so its presence confirms the request reached the health
+		// handler rather than being swallowed by the /{id} catch-all.
+		if !strings.Contains(rec.Body.String(), `"status":"UP"`) {
+			t.Errorf("GET %s body = %q, want it to contain the health report's status field", path, rec.Body.String())
+		}
+	}
+}